@@ -0,0 +1,237 @@
+package simpleforce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Bulk API 2.0 ingest job states, as reported by Salesforce.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_asynch.meta/api_asynch/create_job.htm
+const (
+	JobStateOpen           = "Open"
+	JobStateUploadComplete = "UploadComplete"
+	JobStateInProgress     = "InProgress"
+	JobStateAborted        = "Aborted"
+	JobStateJobComplete    = "JobComplete"
+	JobStateFailed         = "Failed"
+)
+
+// Bulk API 2.0 ingest job operations.
+const (
+	BulkOpInsert     = "insert"
+	BulkOpUpdate     = "update"
+	BulkOpUpsert     = "upsert"
+	BulkOpDelete     = "delete"
+	BulkOpHardDelete = "hardDelete"
+)
+
+// BulkClient drives the Salesforce Bulk API 2.0 for ingest (insert/update/upsert/delete) jobs
+// that operate on large sets of records without the overhead of one SObject call per record.
+type BulkClient struct {
+	client *Client
+}
+
+// BulkJob is the response returned when a Bulk API 2.0 ingest job is created.
+type BulkJob struct {
+	Id                  string `json:"id"`
+	Object              string `json:"object"`
+	Operation           string `json:"operation"`
+	ExternalIdFieldName string `json:"externalIdFieldName,omitempty"`
+	ContentType         string `json:"contentType"`
+	State               string `json:"state"`
+}
+
+// BulkJobInfo is the response returned when polling an ingest job's status.
+type BulkJobInfo struct {
+	Id                     string `json:"id"`
+	Object                 string `json:"object"`
+	Operation              string `json:"operation"`
+	State                  string `json:"state"`
+	NumberRecordsProcessed int    `json:"numberRecordsProcessed"`
+	NumberRecordsFailed    int    `json:"numberRecordsFailed"`
+	ErrorMessage           string `json:"errorMessage,omitempty"`
+}
+
+// Bulk returns a BulkClient that issues Bulk API 2.0 requests on behalf of client.
+func (client *Client) Bulk() *BulkClient {
+	return &BulkClient{client: client}
+}
+
+// CreateJob opens a new Bulk API 2.0 ingest job for object using operation ("insert", "update",
+// "upsert" or "delete"). externalIdField is required for upsert and ignored otherwise.
+func (bc *BulkClient) CreateJob(object, operation, externalIdField string) (*BulkJob, error) {
+	if !bc.client.isLoggedIn() {
+		return nil, ERR_AUTHENTICATION
+	}
+
+	body := map[string]interface{}{
+		"object":      object,
+		"operation":   operation,
+		"contentType": "CSV",
+	}
+	if operation == BulkOpUpsert && externalIdField != "" {
+		body["externalIdFieldName"] = externalIdField
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	u := bc.client.makeURL("jobs/ingest")
+	respData, _, err := bc.client.httpRequest(http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		log.Println(logPrefix, "failed to create bulk job for", object)
+		return nil, err
+	}
+
+	job := &BulkJob{}
+	if err := json.Unmarshal(respData, job); err != nil {
+		return nil, ERR_FAILURE
+	}
+	return job, nil
+}
+
+// UploadCSV uploads CSV-formatted record data for jobID, leaving it in the Open state so
+// that more batches can be uploaded. Call CloseJob once all data has been uploaded to queue
+// the job for processing.
+func (bc *BulkClient) UploadCSV(jobID string, r io.Reader) error {
+	if !bc.client.isLoggedIn() {
+		return ERR_AUTHENTICATION
+	}
+
+	u := bc.client.makeURL(fmt.Sprintf("jobs/ingest/%s/batches", jobID))
+	headers := map[string]string{
+		"Authorization": fmt.Sprintf("Bearer %s", bc.client.sessionID),
+		"Content-Type":  "text/csv",
+	}
+	resp, err := bc.client.doWithRetry(http.MethodPut, u, r, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		log.Println(logPrefix, "bulk CSV upload failed,", resp.StatusCode)
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(resp.Body)
+		return ParseSalesforceError(resp.StatusCode, buf.Bytes())
+	}
+	return nil
+}
+
+// CloseJob marks jobID's data upload as complete, queuing it for processing.
+func (bc *BulkClient) CloseJob(jobID string) error {
+	return bc.patchState(jobID, JobStateUploadComplete)
+}
+
+// AbortJob requests that jobID stop processing.
+func (bc *BulkClient) AbortJob(jobID string) error {
+	return bc.patchState(jobID, JobStateAborted)
+}
+
+func (bc *BulkClient) patchState(jobID, state string) error {
+	if !bc.client.isLoggedIn() {
+		return ERR_AUTHENTICATION
+	}
+
+	data, err := json.Marshal(map[string]string{"state": state})
+	if err != nil {
+		return err
+	}
+
+	u := bc.client.makeURL(fmt.Sprintf("jobs/ingest/%s", jobID))
+	_, _, err = bc.client.httpRequest(http.MethodPatch, u, bytes.NewReader(data))
+	return err
+}
+
+// JobStatus retrieves the current state of jobID.
+func (bc *BulkClient) JobStatus(jobID string) (*BulkJobInfo, error) {
+	if !bc.client.isLoggedIn() {
+		return nil, ERR_AUTHENTICATION
+	}
+
+	u := bc.client.makeURL(fmt.Sprintf("jobs/ingest/%s", jobID))
+	data, _, err := bc.client.httpRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &BulkJobInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, ERR_FAILURE
+	}
+	return info, nil
+}
+
+// SuccessfulRecords returns the CSV results of the records that were processed successfully.
+// The caller is responsible for closing the returned reader.
+func (bc *BulkClient) SuccessfulRecords(jobID string) (io.ReadCloser, error) {
+	return bc.results(jobID, "successfulResults")
+}
+
+// FailedRecords returns the CSV results of the records that failed to process, including the
+// error that caused each failure. The caller is responsible for closing the returned reader.
+func (bc *BulkClient) FailedRecords(jobID string) (io.ReadCloser, error) {
+	return bc.results(jobID, "failedResults")
+}
+
+// UnprocessedRecords returns the CSV of records that were never attempted, e.g. because the
+// job was aborted. The caller is responsible for closing the returned reader.
+func (bc *BulkClient) UnprocessedRecords(jobID string) (io.ReadCloser, error) {
+	return bc.results(jobID, "unprocessedrecords")
+}
+
+func (bc *BulkClient) results(jobID, resource string) (io.ReadCloser, error) {
+	if !bc.client.isLoggedIn() {
+		return nil, ERR_AUTHENTICATION
+	}
+
+	u := bc.client.makeURL(fmt.Sprintf("jobs/ingest/%s/%s", jobID, resource))
+	headers := map[string]string{
+		"Authorization": fmt.Sprintf("Bearer %s", bc.client.sessionID),
+		"Accept":        "text/csv",
+	}
+	resp, err := bc.client.doWithRetry(http.MethodGet, u, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		log.Println(logPrefix, "bulk results fetch failed,", resp.StatusCode)
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(resp.Body)
+		return nil, ParseSalesforceError(resp.StatusCode, buf.Bytes())
+	}
+	return resp.Body, nil
+}
+
+// Wait polls jobID's status every pollInterval until it reaches JobComplete, Failed or
+// Aborted, or until ctx is done.
+func (bc *BulkClient) Wait(ctx context.Context, jobID string, pollInterval time.Duration) (*BulkJobInfo, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		info, err := bc.JobStatus(jobID)
+		if err != nil {
+			return nil, err
+		}
+		switch info.State {
+		case JobStateJobComplete, JobStateFailed, JobStateAborted:
+			return info, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return info, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
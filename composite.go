@@ -0,0 +1,419 @@
+package simpleforce
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxCompositeSubrequests is the number of subrequests Salesforce allows in a single
+// composite or composite/batch call.
+const maxCompositeSubrequests = 25
+
+// maxCollectionRecords is the number of records Salesforce allows in a single sObject
+// Collections create/update/delete call.
+const maxCollectionRecords = 200
+
+// CompositeClient builds a single Salesforce "composite" (or "composite/batch") request out
+// of up to 25 subrequests, so callers can chain creates/updates/queries into one HTTP round
+// trip instead of issuing them one at a time. Call Do to submit against /composite, which
+// supports cross-subrequest referenceId binding, or DoBatch to submit the same subrequests
+// against /composite/batch, where each subrequest is independent and results come back
+// positionally.
+type CompositeClient struct {
+	client      *Client
+	allOrNone   bool
+	subrequests []compositeSubrequest
+}
+
+type compositeSubrequest struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	ReferenceId string      `json:"referenceId"`
+	Body        interface{} `json:"body,omitempty"`
+}
+
+type compositeResult struct {
+	Body           json.RawMessage `json:"body"`
+	HttpStatusCode int             `json:"httpStatusCode"`
+	ReferenceId    string          `json:"referenceId"`
+}
+
+// CompositeResponse holds the per-subrequest results of a composite call, keyed by the
+// referenceId each subrequest was created with.
+type CompositeResponse struct {
+	client  *Client
+	results map[string]compositeResult
+}
+
+// Composite returns a CompositeClient that batches subrequests on behalf of client.
+func (client *Client) Composite() *CompositeClient {
+	return &CompositeClient{client: client}
+}
+
+// AllOrNone sets whether the whole composite request is rolled back if any subrequest fails.
+func (c *CompositeClient) AllOrNone(b bool) *CompositeClient {
+	c.allOrNone = b
+	return c
+}
+
+// Create adds a subrequest that inserts a new sObjectType record, addressable in later
+// subrequests and in the response as referenceId.
+func (c *CompositeClient) Create(referenceId, sObjectType string, fields map[string]interface{}) *CompositeClient {
+	c.subrequests = append(c.subrequests, compositeSubrequest{
+		Method:      http.MethodPost,
+		URL:         fmt.Sprintf("/services/data/v%s/sobjects/%s", c.client.apiVersion, sObjectType),
+		ReferenceId: referenceId,
+		Body:        fields,
+	})
+	return c
+}
+
+// Update adds a subrequest that patches the given fields onto the sObjectType record id.
+func (c *CompositeClient) Update(referenceId, sObjectType, id string, fields map[string]interface{}) *CompositeClient {
+	c.subrequests = append(c.subrequests, compositeSubrequest{
+		Method:      http.MethodPatch,
+		URL:         fmt.Sprintf("/services/data/v%s/sobjects/%s/%s", c.client.apiVersion, sObjectType, id),
+		ReferenceId: referenceId,
+		Body:        fields,
+	})
+	return c
+}
+
+// Delete adds a subrequest that deletes the sObjectType record id.
+func (c *CompositeClient) Delete(referenceId, sObjectType, id string) *CompositeClient {
+	c.subrequests = append(c.subrequests, compositeSubrequest{
+		Method:      http.MethodDelete,
+		URL:         fmt.Sprintf("/services/data/v%s/sobjects/%s/%s", c.client.apiVersion, sObjectType, id),
+		ReferenceId: referenceId,
+	})
+	return c
+}
+
+// Query adds a subrequest that runs soql. Earlier subrequests' results can be referenced in
+// soql via "@{referenceId.fieldName}" binding syntax, per the composite API.
+func (c *CompositeClient) Query(referenceId, soql string) *CompositeClient {
+	c.subrequests = append(c.subrequests, compositeSubrequest{
+		Method:      http.MethodGet,
+		URL:         fmt.Sprintf("/services/data/v%s/query?q=%s", c.client.apiVersion, url.QueryEscape(soql)),
+		ReferenceId: referenceId,
+	})
+	return c
+}
+
+// Do submits the accumulated subrequests as a single composite request.
+func (c *CompositeClient) Do() (*CompositeResponse, error) {
+	if len(c.subrequests) == 0 {
+		return nil, fmt.Errorf("%s composite request has no subrequests", logPrefix)
+	}
+	if len(c.subrequests) > maxCompositeSubrequests {
+		return nil, fmt.Errorf("%s composite request has %d subrequests, max is %d", logPrefix, len(c.subrequests), maxCompositeSubrequests)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"allOrNone":        c.allOrNone,
+		"compositeRequest": c.subrequests,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u := c.client.makeURL("composite")
+	respData, _, err := c.client.httpRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		CompositeResponse []compositeResult `json:"compositeResponse"`
+	}
+	if err := json.Unmarshal(respData, &parsed); err != nil {
+		return nil, ERR_FAILURE
+	}
+
+	results := make(map[string]compositeResult, len(parsed.CompositeResponse))
+	for _, r := range parsed.CompositeResponse {
+		results[r.ReferenceId] = r
+	}
+	return &CompositeResponse{client: c.client, results: results}, nil
+}
+
+// batchSubrequest is one entry of a composite/batch request. Unlike the composite API, batch
+// subrequests address the API relative to the version (no "/services/data/" prefix), carry
+// their payload as "richInput", and cannot reference each other's results.
+type batchSubrequest struct {
+	Method    string      `json:"method"`
+	URL       string      `json:"url"`
+	RichInput interface{} `json:"richInput,omitempty"`
+}
+
+// BatchResult is the outcome of a single composite/batch subrequest, in the same order the
+// subrequest was added via Create/Update/Delete/Query.
+type BatchResult struct {
+	StatusCode int             `json:"statusCode"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// BatchResponse holds the positional results of a composite/batch call.
+type BatchResponse struct {
+	client    *Client
+	HasErrors bool
+	Results   []BatchResult
+}
+
+// DoBatch submits the accumulated subrequests as a single composite/batch request. Unlike
+// Do, batch subrequests are independent of each other (no referenceId binding between them)
+// and results are returned positionally rather than by referenceId.
+func (c *CompositeClient) DoBatch() (*BatchResponse, error) {
+	if len(c.subrequests) == 0 {
+		return nil, fmt.Errorf("%s composite/batch request has no subrequests", logPrefix)
+	}
+	if len(c.subrequests) > maxCompositeSubrequests {
+		return nil, fmt.Errorf("%s composite/batch request has %d subrequests, max is %d", logPrefix, len(c.subrequests), maxCompositeSubrequests)
+	}
+
+	batchRequests := make([]batchSubrequest, len(c.subrequests))
+	for i, sr := range c.subrequests {
+		batchRequests[i] = batchSubrequest{
+			Method:    sr.Method,
+			URL:       strings.TrimPrefix(sr.URL, "/services/data/"),
+			RichInput: sr.Body,
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"batchRequests": batchRequests})
+	if err != nil {
+		return nil, err
+	}
+
+	u := c.client.makeURL("composite/batch")
+	respData, _, err := c.client.httpRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		HasErrors bool          `json:"hasErrors"`
+		Results   []BatchResult `json:"results"`
+	}
+	if err := json.Unmarshal(respData, &parsed); err != nil {
+		return nil, ERR_FAILURE
+	}
+
+	return &BatchResponse{client: c.client, HasErrors: parsed.HasErrors, Results: parsed.Results}, nil
+}
+
+// Record decodes the result at index as an SObject, e.g. for a Create or Update subrequest.
+func (r *BatchResponse) Record(index int) (*SObject, error) {
+	if index < 0 || index >= len(r.Results) {
+		return nil, ERR_DATA_NOT_FOUND
+	}
+	res := r.Results[index]
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, ParseSalesforceError(res.StatusCode, res.Result)
+	}
+
+	obj := &SObject{}
+	if err := json.Unmarshal(res.Result, obj); err != nil {
+		return nil, ERR_FAILURE
+	}
+	obj.setClient(r.client)
+	return obj, nil
+}
+
+// QueryResult decodes the result at index as a QueryResult, for a Query subrequest.
+func (r *BatchResponse) QueryResult(index int) (*QueryResult, error) {
+	if index < 0 || index >= len(r.Results) {
+		return nil, ERR_DATA_NOT_FOUND
+	}
+	res := r.Results[index]
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, ParseSalesforceError(res.StatusCode, res.Result)
+	}
+
+	result := &QueryResult{}
+	if err := json.Unmarshal(res.Result, result); err != nil {
+		return nil, ERR_FAILURE
+	}
+	for idx := range result.Records {
+		result.Records[idx].setClient(r.client)
+	}
+	return result, nil
+}
+
+// Record decodes the subrequest result for referenceId as an SObject, e.g. for a Create or
+// Update subrequest.
+func (r *CompositeResponse) Record(referenceId string) (*SObject, error) {
+	res, ok := r.results[referenceId]
+	if !ok {
+		return nil, ERR_DATA_NOT_FOUND
+	}
+	if res.HttpStatusCode < 200 || res.HttpStatusCode > 299 {
+		return nil, ParseSalesforceError(res.HttpStatusCode, res.Body)
+	}
+
+	obj := &SObject{}
+	if err := json.Unmarshal(res.Body, obj); err != nil {
+		return nil, ERR_FAILURE
+	}
+	obj.setClient(r.client)
+	return obj, nil
+}
+
+// QueryResult decodes the subrequest result for referenceId as a QueryResult, for a Query
+// subrequest.
+func (r *CompositeResponse) QueryResult(referenceId string) (*QueryResult, error) {
+	res, ok := r.results[referenceId]
+	if !ok {
+		return nil, ERR_DATA_NOT_FOUND
+	}
+	if res.HttpStatusCode < 200 || res.HttpStatusCode > 299 {
+		return nil, ParseSalesforceError(res.HttpStatusCode, res.Body)
+	}
+
+	result := &QueryResult{}
+	if err := json.Unmarshal(res.Body, result); err != nil {
+		return nil, ERR_FAILURE
+	}
+	for idx := range result.Records {
+		result.Records[idx].setClient(r.client)
+	}
+	return result, nil
+}
+
+// Tree inserts a tree of up to 200 records, with their relationships, in one call via the
+// sObject Tree endpoint. Each record map may carry a "Relationship__r" key whose value is a
+// []map[string]interface{} of nested child records, per the sObject Tree format.
+func (c *CompositeClient) Tree(objectType string, records []map[string]interface{}) error {
+	if len(records) > maxCollectionRecords {
+		return fmt.Errorf("%s collection request has %d records, max is %d", logPrefix, len(records), maxCollectionRecords)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return err
+	}
+
+	u := c.client.makeURL(fmt.Sprintf("composite/tree/%s", objectType))
+	_, _, err = c.client.httpRequest(http.MethodPost, u, bytes.NewReader(body))
+	return err
+}
+
+// CreateCollection inserts up to 200 sObjectType records in a single call via the sObject
+// Collections endpoint.
+func (c *CompositeClient) CreateCollection(sObjectType string, records []map[string]interface{}, allOrNone bool) ([]*SObject, error) {
+	return c.doCollection(http.MethodPost, withAttributes(sObjectType, records), allOrNone)
+}
+
+// UpdateCollection updates up to 200 sObjectType records, each of which must include its Id,
+// in a single call via the sObject Collections endpoint.
+func (c *CompositeClient) UpdateCollection(sObjectType string, records []map[string]interface{}, allOrNone bool) ([]*SObject, error) {
+	return c.doCollection(http.MethodPatch, withAttributes(sObjectType, records), allOrNone)
+}
+
+// withAttributes returns a copy of records with an "attributes" key identifying sObjectType
+// added to each, leaving the caller's maps untouched.
+func withAttributes(sObjectType string, records []map[string]interface{}) []map[string]interface{} {
+	tagged := make([]map[string]interface{}, len(records))
+	for i, r := range records {
+		cloned := make(map[string]interface{}, len(r)+1)
+		for k, v := range r {
+			cloned[k] = v
+		}
+		cloned["attributes"] = map[string]string{"type": sObjectType}
+		tagged[i] = cloned
+	}
+	return tagged
+}
+
+func (c *CompositeClient) doCollection(method string, records []map[string]interface{}, allOrNone bool) ([]*SObject, error) {
+	if len(records) > maxCollectionRecords {
+		return nil, fmt.Errorf("%s collection request has %d records, max is %d", logPrefix, len(records), maxCollectionRecords)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"allOrNone": allOrNone,
+		"records":   records,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u := c.client.makeURL("composite/sobjects")
+	respData, _, err := c.client.httpRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Id      string `json:"id"`
+		Success bool   `json:"success"`
+	}
+	if err := json.Unmarshal(respData, &results); err != nil {
+		return nil, ERR_FAILURE
+	}
+
+	objs := make([]*SObject, len(results))
+	for i, res := range results {
+		if !res.Success {
+			continue
+		}
+		obj := &SObject{"Id": res.Id}
+		obj.setClient(c.client)
+		objs[i] = obj
+	}
+	return objs, nil
+}
+
+// DeleteCollection deletes up to 200 records by id in a single call via the sObject
+// Collections endpoint.
+func (c *CompositeClient) DeleteCollection(ids []string, allOrNone bool) error {
+	if len(ids) > maxCollectionRecords {
+		return fmt.Errorf("%s collection request has %d ids, max is %d", logPrefix, len(ids), maxCollectionRecords)
+	}
+
+	params := url.Values{
+		"ids":       {strings.Join(ids, ",")},
+		"allOrNone": {fmt.Sprintf("%t", allOrNone)},
+	}
+	u := fmt.Sprintf("%s?%s", c.client.makeURL("composite/sobjects"), params.Encode())
+	_, _, err := c.client.httpRequest(http.MethodDelete, u, nil)
+	return err
+}
+
+// RetrieveCollection fetches fields for multiple sObjectType records by id in a single call
+// via the sObject Collections endpoint, avoiding one GET per id.
+func (c *CompositeClient) RetrieveCollection(sObjectType string, ids []string, fields []string) ([]*SObject, error) {
+	if len(ids) > maxCollectionRecords {
+		return nil, fmt.Errorf("%s collection request has %d ids, max is %d", logPrefix, len(ids), maxCollectionRecords)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"ids":    ids,
+		"fields": fields,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s/%s", c.client.makeURL("composite/sobjects"), sObjectType)
+	respData, _, err := c.client.httpRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	var records []SObject
+	if err := json.Unmarshal(respData, &records); err != nil {
+		return nil, ERR_FAILURE
+	}
+
+	objs := make([]*SObject, len(records))
+	for i := range records {
+		records[i].setClient(c.client)
+		objs[i] = &records[i]
+	}
+	return objs, nil
+}
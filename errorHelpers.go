@@ -27,10 +27,18 @@ var (
 	//ERR_DATA_NOT_FOUND is returned when data is not found
 	ERR_DATA_NOT_FOUND = SfdcError{Message: "data not found", Code: "NOT_FOUND"}
 
+	// ErrAPILimitExceeded is returned instead of a generic failure when Salesforce responds
+	// with the REQUEST_LIMIT_EXCEEDED error code, typically a 403 once the org's daily API
+	// request allotment is exhausted. Check client.APIUsage() or client.Limits() beforehand
+	// to avoid hitting it.
+	ErrAPILimitExceeded = SfdcError{Message: "Salesforce API request limit exceeded", Code: "REQUEST_LIMIT_EXCEEDED"}
+
 	//Error codes implements the retry logic
 	errorCodes = []int{500, 503, 403}
 
-	//ERR_RETRY to implement backoff
+	// ERR_RETRY is deprecated: httpRequest and the other Client HTTP methods now retry
+	// transparently according to Client.SetRetryPolicy, so callers no longer need to check
+	// for this error and re-issue the call themselves.
 	ERR_RETRY = errors.New("retry call")
 )
 
@@ -62,11 +70,15 @@ func ParseSalesforceError(statusCode int, responseBody []byte) (err error) {
 		return err
 	} else {
 		//Successfully parsed json error:
+		if jsonError[0].ErrorCode == ErrAPILimitExceeded.Code {
+			return ErrAPILimitExceeded
+		}
 		err = SfdcError{Message: jsonError[0].Message, Code: jsonError[0].ErrorCode, Extra: map[string]interface{}{"StatusCode": statusCode}}
 		return err
 	}
 }
 
+// RetryLogic is deprecated: retries are now handled internally via Client.SetRetryPolicy.
 func RetryLogic(n int) bool {
 	for i := range errorCodes {
 		if errorCodes[i] == n {
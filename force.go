@@ -39,6 +39,12 @@ type Client struct {
 	instanceURL   string
 	useToolingAPI bool
 	httpClient    *http.Client
+	retryPolicy   RetryPolicy
+	observer      Observer
+
+	apiUsageMu   sync.Mutex
+	apiUsageUsed int
+	apiUsageMax  int
 }
 
 // QueryResult holds the response data from an SOQL query.
@@ -227,16 +233,15 @@ func (client *Client) LoginPassword(username, password, token string) error {
 }
 
 // httpRequest executes an HTTP request to the salesforce server and returns the response data in byte buffer.
+// Retryable failures (429/500/502/503/504 and network errors) are retried transparently
+// according to client.retryPolicy; see SetRetryPolicy.
 func (client *Client) httpRequest(method, url string, body io.Reader) ([]byte, int, error) {
-	req, err := http.NewRequest(method, url, body)
-	if err != nil {
-		return nil, http.StatusInternalServerError, err
+	headers := map[string]string{
+		"Authorization": fmt.Sprintf("Bearer %s", client.sessionID),
+		"Content-Type":  "application/json",
 	}
 
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", client.sessionID))
-	req.Header.Add("Content-Type", "application/json")
-
-	resp, err := client.httpClient.Do(req)
+	resp, err := client.doWithRetry(method, url, body, headers)
 	if err != nil {
 		return nil, http.StatusBadRequest, err
 	}
@@ -266,21 +271,24 @@ func (client *Client) makeURL(req string) string {
 // NewClient creates a new instance of the client.
 func NewClient(url, clientID, apiVersion string) *Client {
 	client := &Client{
-		apiVersion: apiVersion,
-		baseURL:    url,
-		clientID:   clientID,
-		httpClient: &http.Client{},
+		apiVersion:  apiVersion,
+		baseURL:     url,
+		clientID:    clientID,
+		httpClient:  &http.Client{},
+		retryPolicy: DefaultRetryPolicy(),
 	}
 
 	// Append "/" to the end of baseURL if not yet.
 	if !strings.HasSuffix(client.baseURL, "/") {
 		client.baseURL = client.baseURL + "/"
 	}
+	client.instrumentTransport()
 	return client
 }
 
 func (client *Client) SetHttpClient(c *http.Client) {
 	client.httpClient = c
+	client.instrumentTransport()
 }
 
 // DownloadFile downloads a file based on the REST API path given. Saves to filePath.
@@ -292,14 +300,12 @@ func (client *Client) DownloadFile(contentVersionID string, filepath string) err
 	url := fmt.Sprintf("%s%s", baseURL, apiPath)
 
 	// Get the data
-	httpClient := client.httpClient
-	req, err := http.NewRequest("GET", url, nil)
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Authorization", "Bearer "+client.sessionID)
-
-	// resp, err := http.Get(url)
-	resp, err := httpClient.Do(req)
+	headers := map[string]string{
+		"Content-Type":  "application/json; charset=UTF-8",
+		"Accept":        "application/json",
+		"Authorization": "Bearer " + client.sessionID,
+	}
+	resp, err := client.doWithRetry("GET", url, nil, headers)
 	if err != nil {
 		return err
 	}
@@ -330,13 +336,12 @@ func (client *Client) DescribeGlobal() (*SObjectMeta, error) {
 	apiPath := fmt.Sprintf("/services/data/v%s/sobjects", client.apiVersion)
 	baseURL := strings.TrimRight(client.baseURL, "/")
 	url := fmt.Sprintf("%s%s", baseURL, apiPath) // Get the objects
-	httpClient := client.httpClient
-	req, err := http.NewRequest("GET", url, nil)
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Authorization", "Bearer "+client.sessionID)
-	// resp, err := http.Get(url)
-	resp, err := httpClient.Do(req)
+	headers := map[string]string{
+		"Content-Type":  "application/json; charset=UTF-8",
+		"Accept":        "application/json",
+		"Authorization": "Bearer " + client.sessionID,
+	}
+	resp, err := client.doWithRetry("GET", url, nil, headers)
 	if err != nil {
 		return nil, err
 	}
@@ -358,6 +363,10 @@ func (client *Client) DescribeGlobal() (*SObjectMeta, error) {
 }
 
 //Get the list of all created and updated objects, name of the type od the object records and the list will be fetched as per between start date/time and end date/time
+//
+//This fires one GET per id via goroutines; for large result sets prefer
+//client.Composite().RetrieveCollection(name, ids, fields), which fetches up to 200 records
+//in a single call.
 func (client *Client) GetCreatedUpdatedRecords(name, startDateTime, endDateTime string) ([]*SObject, error) {
 	if !client.isLoggedIn() {
 		return nil, ERR_AUTHENTICATION
@@ -365,17 +374,17 @@ func (client *Client) GetCreatedUpdatedRecords(name, startDateTime, endDateTime
 	formatString := "sobjects/%s/updated/?start=%s&end=%s"
 	baseURL := client.makeURL(formatString)
 	url := fmt.Sprintf(baseURL, name, url.QueryEscape(startDateTime), url.QueryEscape(endDateTime))
-	httpClient := client.httpClient
-
-	req, err := http.NewRequest("GET", url, nil)
+	headers := map[string]string{
+		"Content-Type":  "application/json; charset=UTF-8",
+		"Accept":        "application/json",
+		"Authorization": "Bearer " + client.sessionID,
+	}
+	resp, err := client.doWithRetry("GET", url, nil, headers)
 	if err != nil {
-		return nil, ERR_FAILURE
+		return nil, err
 	}
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Authorization", "Bearer "+client.sessionID)
-	// resp, err := http.Get(url)
-	resp, err := httpClient.Do(req)
+	defer resp.Body.Close()
+
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		log.Println(logPrefix, "request failed,", resp.StatusCode)
 		buf := new(bytes.Buffer)
@@ -383,11 +392,7 @@ func (client *Client) GetCreatedUpdatedRecords(name, startDateTime, endDateTime
 		theError := ParseSalesforceError(resp.StatusCode, buf.Bytes())
 		return nil, theError
 	}
-	defer resp.Body.Close()
 
-	if RetryLogic(resp.StatusCode) {
-		return nil, ERR_RETRY
-	}
 	var (
 		sobj  SObject
 		sobjs []*SObject
@@ -436,7 +441,6 @@ func (client *Client) GetCreatedUpdatedRecords(name, startDateTime, endDateTime
 func (client *Client) RefreshToken(clientId, clientSecret, refreshToken string) (interface{}, error) {
 	formatString := "services/oauth2/token"
 	baseURL := client.makeURL(formatString)
-	httpClient := client.httpClient
 	params := url.Values{
 		"format":        {"json"},
 		"grant_type":    {"refresh_token"},
@@ -445,14 +449,11 @@ func (client *Client) RefreshToken(clientId, clientSecret, refreshToken string)
 		"refresh_token": {refreshToken},
 	}
 
-	req, err := http.NewRequest("POST", baseURL, strings.NewReader(params.Encode()))
-	if err != nil {
-		return nil, ERR_FAILURE
+	headers := map[string]string{
+		"Accept":       "application/json",
+		"Content-Type": "application/x-www-form-urlencoded",
 	}
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := httpClient.Do(req)
+	resp, err := client.doWithRetry("POST", baseURL, strings.NewReader(params.Encode()), headers)
 	if err != nil {
 		return nil, err
 	}
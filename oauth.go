@@ -0,0 +1,200 @@
+package simpleforce
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// jwtHeader is the JOSE header for the RS256-signed JWT bearer assertion.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// jwtClaims are the claims required by the Salesforce JWT bearer token flow.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_rest.meta/api_rest/intro_understanding_jwt_oauth_flow.htm
+type jwtClaims struct {
+	Iss string `json:"iss"`
+	Sub string `json:"sub"`
+	Aud string `json:"aud"`
+	Exp int64  `json:"exp"`
+}
+
+func base64URLEncode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// buildSignedJWT builds and RS256-signs a JWT bearer assertion for the JWT bearer flow.
+func buildSignedJWT(consumerKey, subject, audience string, privateKey *rsa.PrivateKey) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: "RS256", Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(jwtClaims{
+		Iss: consumerKey,
+		Sub: subject,
+		Aud: audience,
+		Exp: time.Now().Add(3 * time.Minute).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64URLEncode(header) + "." + base64URLEncode(claims)
+
+	hashed := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64URLEncode(signature), nil
+}
+
+// LoginJWT signs into salesforce using the OAuth 2.0 JWT bearer flow. subject is the
+// username being impersonated and audience is the login/test domain the JWT is issued to
+// (e.g. "https://login.salesforce.com"). privateKey must correspond to the certificate
+// uploaded to the connected app identified by consumerKey.
+func (client *Client) LoginJWT(consumerKey, subject, audience string, privateKey *rsa.PrivateKey) error {
+	assertion, err := buildSignedJWT(consumerKey, subject, audience, privateKey)
+	if err != nil {
+		log.Println(logPrefix, "error occurred building JWT assertion,", err)
+		return err
+	}
+
+	params := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+
+	return client.requestToken(params)
+}
+
+// AuthCodeURL builds the authorization URL for the OAuth 2.0 web server flow, with PKCE
+// (S256) support via codeChallenge. Redirect the user's browser to the returned URL; after
+// they approve access, Salesforce redirects back to redirectURI with a "code" parameter to
+// be passed to ExchangeCode.
+func (client *Client) AuthCodeURL(clientID, redirectURI, scopes, state, codeChallenge string) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {redirectURI},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	if scopes != "" {
+		params.Set("scope", scopes)
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+
+	baseURL := strings.TrimRight(client.baseURL, "/")
+	return fmt.Sprintf("%s/services/oauth2/authorize?%s", baseURL, params.Encode())
+}
+
+// NewPKCEVerifier generates a random code verifier and its S256 code challenge for use with
+// AuthCodeURL and ExchangeCode.
+func NewPKCEVerifier() (verifier, challenge string, err error) {
+	raw := make([]byte, 32)
+	if _, err = rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	verifier = base64URLEncode(raw)
+	sum := sha256.Sum256([]byte(verifier))
+	challenge = base64URLEncode(sum[:])
+	return verifier, challenge, nil
+}
+
+// ExchangeCode completes the OAuth 2.0 web server flow (with PKCE) by exchanging an
+// authorization code obtained via AuthCodeURL for an access token. On success, the client's
+// session is updated so it can be used immediately.
+func (client *Client) ExchangeCode(clientID, clientSecret, code, redirectURI, codeVerifier string) (*Token, error) {
+	params := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {clientID},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {codeVerifier},
+	}
+	if clientSecret != "" {
+		params.Set("client_secret", clientSecret)
+	}
+
+	token, err := client.exchangeToken(params)
+	if err != nil {
+		return nil, err
+	}
+	return token, client.applyToken(token)
+}
+
+// requestToken POSTs params to the OAuth token endpoint and, on success, updates the
+// client's session from the returned token.
+func (client *Client) requestToken(params url.Values) error {
+	token, err := client.exchangeToken(params)
+	if err != nil {
+		return err
+	}
+	return client.applyToken(token)
+}
+
+// exchangeToken POSTs params to /services/oauth2/token and decodes the token response.
+func (client *Client) exchangeToken(params url.Values) (*Token, error) {
+	baseURL := strings.TrimRight(client.baseURL, "/")
+	tokenURL := fmt.Sprintf("%s/services/oauth2/token", baseURL)
+
+	req, err := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		log.Println(logPrefix, "error occurred submitting token request,", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respData, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		log.Println(logPrefix, "token request failed,", resp.StatusCode)
+		return nil, ParseSalesforceError(resp.StatusCode, respData)
+	}
+
+	token := &Token{}
+	if err := json.Unmarshal(respData, token); err != nil {
+		return nil, err
+	}
+	if token.Error != "" {
+		return nil, SfdcError{Message: token.ErrorDescription, Code: token.Error}
+	}
+	return token, nil
+}
+
+// applyToken updates the client's session and instance URL from a successful token response.
+func (client *Client) applyToken(token *Token) error {
+	if token.AccessToken == "" {
+		return ERR_AUTHENTICATION
+	}
+	client.sessionID = token.AccessToken
+	client.instanceURL = token.InstanceUrl
+	log.Println(logPrefix, "session established via OAuth token exchange.")
+	return nil
+}
@@ -0,0 +1,178 @@
+package simpleforce
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures how httpRequest and the other HTTP-issuing Client methods retry
+// transient failures. The zero value is not usable directly; use DefaultRetryPolicy() to
+// obtain sane defaults and tweak from there.
+type RetryPolicy struct {
+	// MaxRetries is the number of additional attempts made after the first one fails. 0
+	// disables retries entirely.
+	MaxRetries int
+	// BaseDelay is the initial backoff delay, doubled on every subsequent attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff delay, including any Retry-After value.
+	MaxDelay time.Duration
+	// RetryableStatuses lists HTTP status codes that should be retried.
+	RetryableStatuses []int
+	// RetryableErrors decides whether a transport-level error (no response received) should
+	// be retried. A nil func retries all such errors except ctx cancellation/deadline, which
+	// are never retried since retrying them can't change the outcome.
+	RetryableErrors func(error) bool
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used by new Clients: 3 retries on 429/500/502/
+// 503/504 and network errors, backing off from 500ms up to 30s with full jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:        3,
+		BaseDelay:         500 * time.Millisecond,
+		MaxDelay:          30 * time.Second,
+		RetryableStatuses: []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+}
+
+// SetRetryPolicy overrides the client's default retry behavior.
+func (client *Client) SetRetryPolicy(p RetryPolicy) {
+	client.retryPolicy = p
+}
+
+func (p RetryPolicy) isRetryableStatus(code int) bool {
+	for _, c := range p.RetryableStatuses {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if p.RetryableErrors != nil {
+		return p.RetryableErrors(err)
+	}
+	return true
+}
+
+// backoffDelay computes the exponential backoff delay for attempt (0-indexed), with full
+// jitter: a random duration in [0, min(MaxDelay, BaseDelay*2^attempt)).
+func (p RetryPolicy) backoffDelay(attempt int) time.Duration {
+	max := float64(p.MaxDelay)
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempt))
+	if delay > max {
+		delay = max
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay)))
+}
+
+// retryAfterDelay parses the Retry-After header, supporting both the delay-seconds and
+// HTTP-date forms. It returns ok=false if the header is absent or unparsable.
+func retryAfterDelay(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := time.ParseDuration(v + "s"); err == nil {
+		return secs, true
+	}
+	if when, err := http.ParseTime(v); err == nil {
+		d := time.Until(when)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// doWithRetry issues an HTTP request, retrying according to client.retryPolicy on retryable
+// statuses or transport errors. body is buffered up front unless it already implements
+// io.Seeker, so it can be rewound and resent on each attempt. The caller is responsible for
+// closing the returned response's body.
+func (client *Client) doWithRetry(method, url string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	policy := client.retryPolicy
+
+	var bodyBytes []byte
+	seeker, seekable := body.(io.Seeker)
+	if body != nil && !seekable {
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		bodyBytes = b
+	}
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		var reqBody io.Reader
+		switch {
+		case bodyBytes != nil:
+			reqBody = bytes.NewReader(bodyBytes)
+		case seekable:
+			seeker.Seek(0, io.SeekStart)
+			reqBody = body
+		}
+
+		req, err := http.NewRequest(method, url, reqBody)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range headers {
+			req.Header.Add(k, v)
+		}
+
+		resp, err := client.httpClient.Do(req)
+
+		if err == nil && !policy.isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if err != nil && !policy.isRetryableError(err) {
+			return nil, err
+		}
+
+		lastErr = err
+		if attempt >= policy.MaxRetries {
+			if resp != nil {
+				return resp, nil
+			}
+			return nil, lastErr
+		}
+
+		var delay time.Duration
+		if resp != nil {
+			if d, ok := retryAfterDelay(resp.Header); ok {
+				delay = d
+				if delay > policy.MaxDelay {
+					delay = policy.MaxDelay
+				}
+			} else {
+				delay = policy.backoffDelay(attempt)
+			}
+			resp.Body.Close()
+		} else {
+			delay = policy.backoffDelay(attempt)
+		}
+
+		log.Println(logPrefix, "retrying request, attempt", attempt+1, "after", delay)
+		if client.observer != nil {
+			client.observer.OnRetry(req, attempt+1, lastErr)
+		}
+		time.Sleep(delay)
+	}
+}
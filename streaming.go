@@ -0,0 +1,331 @@
+package simpleforce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"sync"
+	"time"
+)
+
+// replayFromTip and replayFromEarliest are well-known replay-id values recognized by the
+// Streaming API extension. Any other value resumes from that specific event position.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_streaming.meta/api_streaming/using_streaming_api_durability.htm
+const (
+	replayFromTip      = -1
+	replayFromEarliest = -2
+)
+
+// StreamingMessage is a single event delivered on a subscribed channel.
+type StreamingMessage struct {
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// bayeuxMessage models the subset of the Bayeux protocol envelope simpleforce sends and
+// receives when talking to Salesforce's CometD endpoint.
+// Ref: https://developer.salesforce.com/docs/atlas.en-us.api_streaming.meta/api_streaming/intro_stream.htm
+type bayeuxMessage struct {
+	Channel                  string                 `json:"channel"`
+	ClientId                 string                 `json:"clientId,omitempty"`
+	Subscription             string                 `json:"subscription,omitempty"`
+	Version                  string                 `json:"version,omitempty"`
+	MinimumVersion           string                 `json:"minimumVersion,omitempty"`
+	SupportedConnectionTypes []string               `json:"supportedConnectionTypes,omitempty"`
+	ConnectionType           string                 `json:"connectionType,omitempty"`
+	Successful               bool                   `json:"successful,omitempty"`
+	Error                    string                 `json:"error,omitempty"`
+	Ext                      map[string]interface{} `json:"ext,omitempty"`
+	Advice                   *bayeuxAdvice          `json:"advice,omitempty"`
+	Data                     json.RawMessage        `json:"data,omitempty"`
+}
+
+type bayeuxAdvice struct {
+	Reconnect string `json:"reconnect,omitempty"`
+	Interval  int    `json:"interval,omitempty"`
+}
+
+// StreamingClient subscribes to PushTopics, Platform Events and Change Data Capture channels
+// over the CometD long-polling Bayeux protocol, reusing the bearer session of the Client it
+// was created from.
+type StreamingClient struct {
+	client   *Client
+	mu       sync.Mutex
+	clientID string
+	channels map[string]*subscription
+	cancel   context.CancelFunc
+}
+
+// subscription pairs a delivered-message channel with its own lifecycle signal, so
+// connectLoop (the only goroutine that sends on ch) and Unsubscribe/Disconnect (the only
+// goroutines that close it) can coordinate without a send-on-closed-channel race: sendMu is
+// held for the duration of any send attempt, and is also acquired before close(ch), so the
+// two can never run concurrently.
+type subscription struct {
+	ch     chan StreamingMessage
+	done   chan struct{}
+	sendMu sync.Mutex
+}
+
+// Streaming returns a StreamingClient that subscribes to channels on behalf of client. The
+// CometD handshake returns a "clientId" that Salesforce also tracks via a session cookie for
+// node affinity; client's http.Client is given a CookieJar (if it doesn't already have one)
+// so that cookie is carried on every subsequent /meta/connect and /meta/subscribe call.
+func (client *Client) Streaming() *StreamingClient {
+	if client.httpClient.Jar == nil {
+		if jar, err := cookiejar.New(nil); err == nil {
+			client.httpClient.Jar = jar
+		}
+	}
+	return &StreamingClient{
+		client:   client,
+		channels: make(map[string]*subscription),
+	}
+}
+
+func (sc *StreamingClient) cometdURL() string {
+	baseURL := strings.TrimRight(sc.client.instanceURL, "/")
+	return fmt.Sprintf("%s/cometd/%s", baseURL, sc.client.apiVersion)
+}
+
+// send posts a Bayeux message to the CometD endpoint and returns the decoded response array.
+func (sc *StreamingClient) send(msg bayeuxMessage) ([]bayeuxMessage, error) {
+	data, err := json.Marshal([]bayeuxMessage{msg})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sc.cometdURL(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", sc.client.sessionID))
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := sc.client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respData, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ERR_FAILURE
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		log.Println(logPrefix, "cometd request failed,", resp.StatusCode)
+		return nil, ParseSalesforceError(resp.StatusCode, respData)
+	}
+
+	var messages []bayeuxMessage
+	if err := json.Unmarshal(respData, &messages); err != nil {
+		return nil, ERR_FAILURE
+	}
+	return messages, nil
+}
+
+// handshake performs the Bayeux /meta/handshake and stores the clientId used for all
+// subsequent /meta/connect and /meta/subscribe calls.
+func (sc *StreamingClient) handshake() error {
+	messages, err := sc.send(bayeuxMessage{
+		Channel:                  "/meta/handshake",
+		Version:                  "1.0",
+		MinimumVersion:           "1.0",
+		SupportedConnectionTypes: []string{"long-polling"},
+	})
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 || !messages[0].Successful {
+		return ERR_AUTHENTICATION
+	}
+	sc.clientID = messages[0].ClientId
+	return nil
+}
+
+// Subscribe subscribes to channel (e.g. "/event/MyEvent__e", "/data/AccountChangeEvent") and
+// returns a channel on which matching messages are delivered until ctx is cancelled or
+// Unsubscribe/Disconnect is called. replayID optionally resumes delivery from a stored event
+// position; omit it to start from the tip of the stream.
+func (sc *StreamingClient) Subscribe(ctx context.Context, channel string, replayID ...int) (<-chan StreamingMessage, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if sc.clientID == "" {
+		if err := sc.handshake(); err != nil {
+			return nil, err
+		}
+	}
+
+	replay := replayFromTip
+	if len(replayID) > 0 {
+		replay = replayID[0]
+	}
+
+	msg := bayeuxMessage{
+		Channel:      "/meta/subscribe",
+		ClientId:     sc.clientID,
+		Subscription: channel,
+		Ext: map[string]interface{}{
+			"replay": map[string]interface{}{channel: replay},
+		},
+	}
+	messages, err := sc.send(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, ERR_FAILURE
+	}
+	if !messages[0].Successful {
+		return nil, fmt.Errorf("%s subscribe to %s failed: %s", logPrefix, channel, messages[0].Error)
+	}
+
+	sub := &subscription{
+		ch:   make(chan StreamingMessage, 16),
+		done: make(chan struct{}),
+	}
+	sc.channels[channel] = sub
+
+	if sc.cancel == nil {
+		connectCtx, cancel := context.WithCancel(context.Background())
+		sc.cancel = cancel
+		go sc.connectLoop(connectCtx)
+	}
+
+	go func() {
+		<-ctx.Done()
+		sc.Unsubscribe(channel)
+	}()
+
+	return sub.ch, nil
+}
+
+// connectLoop runs the long-polling /meta/connect cycle until ctx is cancelled or the server
+// advises that reconnecting is pointless. Failed /meta/connect attempts back off (instead of
+// busy-looping) using the client's retry policy, so a down or unreachable CometD endpoint
+// doesn't turn into a tight loop hammering the org with requests.
+func (sc *StreamingClient) connectLoop(ctx context.Context) {
+	policy := sc.client.retryPolicy
+	failures := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		messages, err := sc.send(bayeuxMessage{
+			Channel:        "/meta/connect",
+			ClientId:       sc.clientID,
+			ConnectionType: "long-polling",
+		})
+		if err != nil {
+			log.Println(logPrefix, "cometd connect failed,", err)
+			delay := policy.backoffDelay(failures)
+			failures++
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			continue
+		}
+		failures = 0
+
+		for _, m := range messages {
+			switch {
+			case m.Channel == "/meta/connect" && !m.Successful && m.Advice != nil && m.Advice.Reconnect == "none":
+				return
+			case m.Channel == "/meta/connect" && !m.Successful && m.Advice != nil && m.Advice.Reconnect == "handshake":
+				if err := sc.handshake(); err != nil {
+					log.Println(logPrefix, "cometd re-handshake failed,", err)
+					return
+				}
+			case m.Data != nil:
+				sc.mu.Lock()
+				sub, ok := sc.channels[m.Channel]
+				sc.mu.Unlock()
+				if ok {
+					sub.sendMu.Lock()
+					select {
+					case <-sub.done:
+						// Already unsubscribed/disconnected (ch may be closed); drop it.
+					default:
+						select {
+						case sub.ch <- StreamingMessage{Channel: m.Channel, Data: m.Data}:
+						case <-sub.done:
+							// Unsubscribed/disconnected while this message was in flight; drop it.
+						}
+					}
+					sub.sendMu.Unlock()
+				}
+			}
+		}
+	}
+}
+
+// Unsubscribe stops delivery on channel and closes its message channel. It is safe to call
+// even if connectLoop is in the middle of delivering a message on channel.
+func (sc *StreamingClient) Unsubscribe(channel string) error {
+	sc.mu.Lock()
+	sub, ok := sc.channels[channel]
+	if ok {
+		delete(sc.channels, channel)
+	}
+	sc.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	_, err := sc.send(bayeuxMessage{
+		Channel:      "/meta/unsubscribe",
+		ClientId:     sc.clientID,
+		Subscription: channel,
+	})
+
+	closeSubscription(sub)
+	return err
+}
+
+// Disconnect tears down the Bayeux session and closes all subscribed channels.
+func (sc *StreamingClient) Disconnect() error {
+	sc.mu.Lock()
+	if sc.cancel != nil {
+		sc.cancel()
+		sc.cancel = nil
+	}
+	subs := sc.channels
+	sc.channels = make(map[string]*subscription)
+	sc.mu.Unlock()
+
+	var err error
+	if sc.clientID != "" {
+		_, err = sc.send(bayeuxMessage{Channel: "/meta/disconnect", ClientId: sc.clientID})
+		sc.clientID = ""
+	}
+
+	for _, sub := range subs {
+		closeSubscription(sub)
+	}
+	return err
+}
+
+// closeSubscription closes sub.done so any send blocked in connectLoop's select aborts, then
+// waits for that send attempt to finish (sendMu) before closing sub.ch. Since connectLoop is
+// the only sender and always holds sendMu while sending, acquiring it here guarantees the
+// close is never racing a send.
+func closeSubscription(sub *subscription) {
+	close(sub.done)
+	sub.sendMu.Lock()
+	close(sub.ch)
+	sub.sendMu.Unlock()
+}
@@ -0,0 +1,133 @@
+package simpleforce
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Observer receives callbacks for the lifecycle of HTTP requests issued by a Client, so
+// callers can wire up Prometheus/OpenTelemetry metrics without forking the library. All
+// methods must be safe for concurrent use, since requests may be in flight on multiple
+// goroutines.
+type Observer interface {
+	// OnRequest is called immediately before a request is sent.
+	OnRequest(req *http.Request)
+	// OnResponse is called after a response (or transport error) is received for req.
+	// resp is nil if the round trip failed before a response was received.
+	OnResponse(req *http.Request, resp *http.Response, dur time.Duration)
+	// OnRetry is called before a retried request is resent, with the 1-indexed attempt
+	// number and the transport error that triggered the retry; err is nil when the retry
+	// was triggered by a retryable response status.
+	OnRetry(req *http.Request, attempt int, err error)
+}
+
+// instrumentedTransport wraps an http.RoundTripper to track the client's Salesforce API
+// usage and to invoke its Observer, if any, around each round trip.
+type instrumentedTransport struct {
+	next   http.RoundTripper
+	client *Client
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.client.observer != nil {
+		t.client.observer.OnRequest(req)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	dur := time.Since(start)
+
+	if t.client.observer != nil {
+		t.client.observer.OnResponse(req, resp, dur)
+	}
+
+	if resp != nil {
+		if info := resp.Header.Get("Sforce-Limit-Info"); info != "" {
+			t.client.recordAPIUsage(info)
+		}
+	}
+	return resp, err
+}
+
+// SetObserver registers obs to receive OnRequest/OnResponse/OnRetry callbacks for every HTTP
+// request the client issues. Passing nil stops notifying; API usage tracking keeps working
+// either way, since the transport is wrapped unconditionally by NewClient/SetHttpClient.
+func (client *Client) SetObserver(obs Observer) {
+	client.observer = obs
+	client.instrumentTransport()
+}
+
+// instrumentTransport wraps the client's current http.Client transport with
+// instrumentedTransport, unless it is already wrapped, so Sforce-Limit-Info tracking works
+// even if the caller never registers an Observer.
+func (client *Client) instrumentTransport() {
+	if _, ok := client.httpClient.Transport.(*instrumentedTransport); ok {
+		return
+	}
+	next := client.httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	client.httpClient.Transport = &instrumentedTransport{next: next, client: client}
+}
+
+// recordAPIUsage parses a "Sforce-Limit-Info: api-usage=10234/5000000" response header.
+func (client *Client) recordAPIUsage(header string) {
+	idx := strings.Index(header, "api-usage=")
+	if idx == -1 {
+		return
+	}
+	usage := strings.SplitN(header[idx+len("api-usage="):], "/", 2)
+	if len(usage) != 2 {
+		return
+	}
+
+	usedN, err1 := strconv.Atoi(usage[0])
+	maxN, err2 := strconv.Atoi(strings.SplitN(usage[1], ",", 2)[0])
+	if err1 != nil || err2 != nil {
+		return
+	}
+
+	client.apiUsageMu.Lock()
+	client.apiUsageUsed = usedN
+	client.apiUsageMax = maxN
+	client.apiUsageMu.Unlock()
+}
+
+// APIUsage returns the most recently observed API usage reported by Salesforce via the
+// Sforce-Limit-Info response header, as (used, max) request counts for the current day.
+func (client *Client) APIUsage() (used, max int) {
+	client.apiUsageMu.Lock()
+	defer client.apiUsageMu.Unlock()
+	return client.apiUsageUsed, client.apiUsageMax
+}
+
+// Limit describes one named org limit as returned by the /limits endpoint, e.g.
+// "DailyApiRequests".
+type Limit struct {
+	Max       int `json:"Max"`
+	Remaining int `json:"Remaining"`
+}
+
+// Limits fetches the current value of every org limit (API requests, data storage, etc.)
+// from the /limits endpoint.
+func (client *Client) Limits() (map[string]Limit, error) {
+	if !client.isLoggedIn() {
+		return nil, ERR_AUTHENTICATION
+	}
+
+	u := client.makeURL("limits")
+	data, _, err := client.httpRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make(map[string]Limit)
+	if err := json.Unmarshal(data, &limits); err != nil {
+		return nil, ERR_FAILURE
+	}
+	return limits, nil
+}